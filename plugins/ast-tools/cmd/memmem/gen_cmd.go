@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+
+	"github.com/baleen37/memmem/plugins/ast-tools/gen"
+)
+
+// substitutionList implements flag.Value to accept repeated -type flags.
+type substitutionList []gen.Substitution
+
+func (l *substitutionList) String() string {
+	return fmt.Sprint([]gen.Substitution(*l))
+}
+
+func (l *substitutionList) Set(s string) error {
+	sub, err := gen.ParseSubstitution(s)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, sub)
+	return nil
+}
+
+// runGen implements `memmem gen -in FILE -type T=Concrete [-type ...] -out FILE`.
+func runGen(args []string) error {
+	fs := newFlagSet("gen")
+	in := fs.String("in", "", "template source file")
+	out := fs.String("out", "", "specialized output file")
+	var subs substitutionList
+	fs.Var(&subs, "type", "placeholder substitution Placeholder=Concrete; may be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" || *out == "" || len(subs) == 0 {
+		return fmt.Errorf("-in, -out, and at least one -type are required")
+	}
+
+	templateSrc, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+
+	existing, err := existingNames(filepath.Dir(*out), *out)
+	if err != nil {
+		return err
+	}
+
+	specialized, err := gen.Specialize(templateSrc, subs, existing)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(*out, specialized, 0o644)
+}
+
+// existingNames collects the top-level identifiers already declared in dir,
+// excluding skipFile, so gen.Specialize can detect collisions.
+func existingNames(dir, skipFile string) (map[string]bool, error) {
+	names := map[string]bool{}
+	fset := token.NewFileSet()
+	skip, _ := filepath.Abs(skipFile)
+
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		abs, _ := filepath.Abs(filepath.Join(dir, fi.Name()))
+		return abs != skip
+	}, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return names, nil
+		}
+		return nil, err
+	}
+
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				collectTopLevelNames(decl, names)
+			}
+		}
+	}
+	return names, nil
+}
+
+func collectTopLevelNames(decl ast.Decl, names map[string]bool) {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil {
+			names[d.Name.Name] = true
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names[s.Name.Name] = true
+			case *ast.ValueSpec:
+				for _, n := range s.Names {
+					names[n.Name] = true
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"os"
+
+	"github.com/baleen37/memmem/plugins/ast-tools/lsp"
+)
+
+// runLSP implements `memmem lsp [-root DIR]`, serving the Language Server
+// Protocol over stdin/stdout.
+func runLSP(args []string) error {
+	fs := newFlagSet("lsp")
+	root := fs.String("root", ".", "workspace root to search for pattern matches")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	srv := lsp.NewServer(*root)
+	return srv.Serve(os.Stdin, os.Stdout)
+}
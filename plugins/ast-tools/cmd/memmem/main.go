@@ -0,0 +1,63 @@
+// Command memmem is a structural search-and-rewrite tool for Go source,
+// built on package pattern and package rewrite.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	var err error
+	switch cmd {
+	case "search":
+		err = runSearch(args)
+	case "rewrite":
+		err = runRewrite(args)
+	case "query":
+		err = runQuery(args)
+	case "gen":
+		err = runGen(args)
+	case "lsp":
+		err = runLSP(args)
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "memmem: unknown command %q\n", cmd)
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "memmem: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: memmem <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  search    find pattern matches and print them as json|jsonl|grep|vimgrep")
+	fmt.Fprintln(os.Stderr, "  rewrite   apply a pattern -> replacement rewrite across files")
+	fmt.Fprintln(os.Stderr, "  query     ask semantic questions (implements, method signature) about a program")
+	fmt.Fprintln(os.Stderr, "  gen       specialize a generic.Type template into concrete code")
+	fmt.Fprintln(os.Stderr, "  lsp       serve pattern search over the Language Server Protocol")
+}
+
+// newFlagSet builds a FlagSet that shares main's usage convention.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: memmem %s [flags] path...\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/baleen37/memmem/plugins/ast-tools/sema"
+)
+
+// runQuery implements `memmem query [-implements IFACE] [-method SIG] pattern...`.
+func runQuery(args []string) error {
+	fs := newFlagSet("query")
+	implements := fs.String("implements", "", "list types implementing this qualified interface, e.g. fmt.Stringer")
+	method := fs.String("method", "", "list methods matching this signature, e.g. 'func(string) string'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *implements == "" && *method == "" {
+		return fmt.Errorf("one of -implements or -method is required")
+	}
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	prog, err := sema.LoadPackages(patterns...)
+	if err != nil {
+		return err
+	}
+
+	if *implements != "" {
+		for _, obj := range prog.Implementors(*implements) {
+			fmt.Fprintf(os.Stdout, "%s\t%s\n", obj.Pkg().Path(), obj.Name())
+		}
+	}
+	if *method != "" {
+		for _, obj := range prog.MethodsMatching(*method) {
+			fmt.Fprintf(os.Stdout, "%s\t%s\n", obj.Pkg().Path(), obj.Name())
+		}
+	}
+	return nil
+}
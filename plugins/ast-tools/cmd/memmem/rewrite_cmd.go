@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/baleen37/memmem/plugins/ast-tools/rewrite"
+)
+
+// runRewrite implements `memmem rewrite -from PAT -to PAT [-diff] [-w] path...`.
+func runRewrite(args []string) error {
+	fs := newFlagSet("rewrite")
+	from := fs.String("from", "", "pattern to match, e.g. 'fmt.Sprintf($fmt, $args(...))'")
+	to := fs.String("to", "", "replacement pattern, with the same metavariables as -from")
+	write := fs.Bool("w", false, "write result to (source) file instead of stdout")
+	diff := fs.Bool("diff", false, "print a unified diff instead of the rewritten source")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		return fmt.Errorf("-from and -to are required")
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		return fmt.Errorf("no input files")
+	}
+
+	rw, err := rewrite.Compile(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := rewriteFile(rw, path, *write, *diff); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func rewriteFile(rw *rewrite.Rewriter, path string, write, diffMode bool) error {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, original, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	if !rw.Apply(fset, f) {
+		return nil
+	}
+
+	out, err := rewrite.Source(fset, f)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case diffMode:
+		d, err := diffBytes(filepath.Base(path), original, out)
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(d)
+	case write:
+		return os.WriteFile(path, out, 0o644)
+	default:
+		os.Stdout.Write(out)
+	}
+	return nil
+}
+
+// diffBytes shells out to the system diff tool, following the same
+// convention gofmt uses for its -d flag.
+func diffBytes(label string, before, after []byte) ([]byte, error) {
+	beforeFile, err := os.CreateTemp("", "memmem-before-*.go")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(beforeFile.Name())
+	afterFile, err := os.CreateTemp("", "memmem-after-*.go")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(afterFile.Name())
+
+	if _, err := beforeFile.Write(before); err != nil {
+		return nil, err
+	}
+	if _, err := afterFile.Write(after); err != nil {
+		return nil, err
+	}
+	beforeFile.Close()
+	afterFile.Close()
+
+	out, err := exec.Command("diff", "-u", beforeFile.Name(), afterFile.Name()).Output()
+	if len(out) == 0 && err != nil {
+		return nil, fmt.Errorf("diff %s: %w", label, err)
+	}
+	return replaceTempNames(out, beforeFile.Name(), afterFile.Name(), label), nil
+}
+
+func replaceTempNames(diff []byte, beforeName, afterName, label string) []byte {
+	diff = bytes.ReplaceAll(diff, []byte(beforeName), []byte(label+".orig"))
+	diff = bytes.ReplaceAll(diff, []byte(afterName), []byte(label))
+	return diff
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+
+	"github.com/baleen37/memmem/plugins/ast-tools/output"
+	"github.com/baleen37/memmem/plugins/ast-tools/pattern"
+)
+
+// runSearch implements `memmem search -pattern PAT [--format=...] [--stats] path...`.
+func runSearch(args []string) error {
+	fs := newFlagSet("search")
+	pat := fs.String("pattern", "", "pattern to search for, e.g. 'func $name($_) string { return $_ }'")
+	format := fs.String("format", "jsonl", "output format: json|jsonl|grep|vimgrep")
+	stats := fs.Bool("stats", false, "print a summary line after the stream")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *pat == "" {
+		return fmt.Errorf("-pattern is required")
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		return fmt.Errorf("no input files")
+	}
+
+	p, err := pattern.Parse(*pat)
+	if err != nil {
+		return err
+	}
+	w, err := output.New(*format, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	var total output.Stats
+	for _, path := range paths {
+		n, err := searchFile(p, path, w)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if n > 0 {
+			total.Files++
+		}
+		total.Matches += n
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if *stats {
+		return output.PrintStats(os.Stdout, total)
+	}
+	return nil
+}
+
+func searchFile(p *pattern.Pattern, path string, w output.Writer) (int, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return 0, err
+	}
+
+	matches := p.Match(fset, f)
+	for _, m := range matches {
+		end := fset.Position(m.Node.End())
+		captures := make(map[string]output.Capture, len(m.Captures))
+		for name, node := range m.Captures {
+			pos := fset.Position(node.Pos())
+			captures[name] = output.Capture{
+				File:    pos.Filename,
+				Line:    pos.Line,
+				Col:     pos.Column,
+				Snippet: renderNode(fset, node),
+			}
+		}
+		if err := w.Write(output.Match{
+			File:     m.Pos.Filename,
+			Line:     m.Pos.Line,
+			Col:      m.Pos.Column,
+			EndLine:  end.Line,
+			EndCol:   end.Column,
+			Kind:     fmt.Sprintf("%T", m.Node),
+			Snippet:  renderNode(fset, m.Node),
+			Captures: captures,
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(matches), nil
+}
+
+func renderNode(fset *token.FileSet, n ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, n); err != nil {
+		return ""
+	}
+	return buf.String()
+}
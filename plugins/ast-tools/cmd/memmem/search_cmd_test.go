@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/baleen37/memmem/plugins/ast-tools/output"
+	"github.com/baleen37/memmem/plugins/ast-tools/pattern"
+)
+
+const sampleFixture = "../../tests/fixtures/multi-lang/sample.go"
+
+func TestSearchFile_PatternFlagExampleOverSample(t *testing.T) {
+	// The exact pattern shown in runSearch's -pattern flag usage string.
+	p, err := pattern.Parse("func $name($_) string { return $_ }")
+	if err != nil {
+		t.Fatalf("pattern.Parse: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := output.New("jsonl", &buf)
+	if err != nil {
+		t.Fatalf("output.New: %v", err)
+	}
+
+	n, err := searchFile(p, sampleFixture, w)
+	if err != nil {
+		t.Fatalf("searchFile: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if n != 2 {
+		t.Fatalf("searchFile matched %d functions, want 2 (Greet and Farewell)", n)
+	}
+	for _, snippet := range []string{`"name":{"file"`, `"snippet":"Greet"`, `"snippet":"Farewell"`} {
+		if !strings.Contains(buf.String(), snippet) {
+			t.Errorf("jsonl output missing %s; output:\n%s", snippet, buf.String())
+		}
+	}
+}
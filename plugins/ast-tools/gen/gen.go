@@ -0,0 +1,223 @@
+// Package gen specializes a generic Go source template into concrete code,
+// following the `type T generic.Type` convention popularized by genny: a
+// template declares placeholder types with that pseudo-declaration, and
+// Specialize substitutes a concrete type for each placeholder wherever it
+// appears, including in derived identifiers like TQueue -> GreeterQueue.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// placeholderSelector is the package.Type a generic placeholder declaration
+// must reference, e.g. `type T generic.Type`.
+const placeholderSelector = "generic.Type"
+
+// genericImportPath is the import path of the placeholderSelector's package,
+// pruned from the output once the last `type T generic.Type` referencing it
+// has been stripped.
+const genericImportPath = "generic"
+
+// Substitution maps a placeholder type name to the concrete type that
+// should replace it, e.g. T=Greeter.
+type Substitution struct {
+	Placeholder string
+	Concrete    string
+}
+
+// ParseSubstitution parses a "T=Greeter" flag value into a Substitution.
+func ParseSubstitution(s string) (Substitution, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Substitution{}, fmt.Errorf("gen: %q is not in Placeholder=Concrete form", s)
+	}
+	return Substitution{Placeholder: parts[0], Concrete: parts[1]}, nil
+}
+
+// Specialize parses the template source, drops its `type T generic.Type`
+// declarations, and renames every reference to a placeholder - including
+// as a prefix of a longer identifier such as TQueue - to the matching
+// concrete type. existingNames is consulted to detect collisions between a
+// renamed identifier and a declaration already present in the target
+// package; Specialize returns an error naming the first collision found.
+func Specialize(templateSrc []byte, subs []Substitution, existingNames map[string]bool) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", templateSrc, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("gen: parse template: %w", err)
+	}
+
+	placeholders := map[string]bool{}
+	f.Decls = filterDecls(f.Decls, func(d ast.Decl) bool {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			return true
+		}
+		for _, spec := range gd.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || !isGenericPlaceholder(ts.Type) {
+				continue
+			}
+			placeholders[ts.Name.Name] = true
+		}
+		return len(gd.Specs) != 1 || !placeholders[gd.Specs[0].(*ast.TypeSpec).Name.Name]
+	})
+
+	rename := renameFunc(subs, placeholders)
+	for _, name := range renamedIdentifiers(f, rename) {
+		if existingNames[name] {
+			return nil, fmt.Errorf("gen: generated identifier %q collides with an existing declaration", name)
+		}
+	}
+
+	astutil.Apply(f, nil, func(c *astutil.Cursor) bool {
+		id, ok := c.Node().(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if newName, ok := rename(id.Name); ok {
+			id.Name = newName
+		}
+		return true
+	})
+
+	// The placeholder decl stripped above was generic.Type's only use in a
+	// genny-style template; once it's gone the import must go too, or the
+	// specialized output fails to build with an unused import.
+	if !astutil.UsesImport(f, genericImportPath) {
+		astutil.DeleteImport(fset, f, genericImportPath)
+	}
+
+	rewriteComments(f, rename)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, fmt.Errorf("gen: format output: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// isGenericPlaceholder reports whether t is the `generic.Type` selector
+// expression used to mark a placeholder declaration.
+func isGenericPlaceholder(t ast.Expr) bool {
+	sel, ok := t.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name+"."+sel.Sel.Name == placeholderSelector
+}
+
+// filterDecls returns the subset of decls for which keep reports true.
+func filterDecls(decls []ast.Decl, keep func(ast.Decl) bool) []ast.Decl {
+	out := decls[:0]
+	for _, d := range decls {
+		if keep(d) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// renameFunc builds a function mapping an identifier in the template to its
+// specialized form: an exact placeholder match is replaced outright, and a
+// placeholder occurring as its own camelCase word within a longer identifier
+// (as in TQueue, or NewTQueue) has that word replaced, matching genny's
+// convention for deriving specialized names from the placeholder.
+func renameFunc(subs []Substitution, placeholders map[string]bool) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		for _, sub := range subs {
+			if !placeholders[sub.Placeholder] {
+				continue
+			}
+			if renamed, ok := renameWord(name, sub.Placeholder, sub.Concrete); ok {
+				return renamed, true
+			}
+		}
+		return "", false
+	}
+}
+
+// renameWord replaces every occurrence of placeholder in name that stands on
+// its own camelCase word boundary - not merely as a prefix, so NewTQueue's
+// embedded T is found - with concrete. It leaves occurrences that are only a
+// prefix of a longer word, such as T inside Type, untouched.
+func renameWord(name, placeholder, concrete string) (string, bool) {
+	var b strings.Builder
+	matched := false
+	for i := 0; i < len(name); {
+		if strings.HasPrefix(name[i:], placeholder) && isWordBoundary(name, i, i+len(placeholder)) {
+			b.WriteString(concrete)
+			i += len(placeholder)
+			matched = true
+			continue
+		}
+		b.WriteByte(name[i])
+		i++
+	}
+	if !matched {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// isWordBoundary reports whether name[start:end] sits on camelCase word
+// boundaries: the byte before start, if any, must not be an uppercase letter
+// continuing the same run, and the byte at end, if any, must not be a
+// lowercase letter continuing the match into a longer word.
+func isWordBoundary(name string, start, end int) bool {
+	leftOK := start == 0 || !isLetter(name[start-1]) || isLower(name[start-1])
+	rightOK := end == len(name) || !isLower(name[end])
+	return leftOK && rightOK
+}
+
+func isLetter(b byte) bool { return isLower(b) || isUpper(b) }
+func isLower(b byte) bool  { return b >= 'a' && b <= 'z' }
+func isUpper(b byte) bool  { return b >= 'A' && b <= 'Z' }
+
+// identWordRe matches a single Go-identifier-shaped word within free text,
+// used by rewriteComments to find placeholder mentions in doc comments.
+var identWordRe = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// rewriteComments applies rename to every identifier-shaped word in f's
+// comments, so doc comments like "TQueue is a FIFO queue of T." track the
+// same renames the astutil.Apply pass already made to the code itself.
+func rewriteComments(f *ast.File, rename func(string) (string, bool)) {
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			c.Text = identWordRe.ReplaceAllStringFunc(c.Text, func(word string) string {
+				if renamed, ok := rename(word); ok {
+					return renamed
+				}
+				return word
+			})
+		}
+	}
+}
+
+// renamedIdentifiers collects, without mutating f, every identifier name
+// that rename would produce if applied - used to check for collisions
+// before committing to the rewrite.
+func renamedIdentifiers(f *ast.File, rename func(string) (string, bool)) []string {
+	var names []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if newName, ok := rename(id.Name); ok {
+			names = append(names, newName)
+		}
+		return true
+	})
+	return names
+}
@@ -0,0 +1,34 @@
+package gen
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const queueFixture = "testdata/generic-queue/queue.go"
+
+func TestSpecialize_GreeterQueue(t *testing.T) {
+	src, err := os.ReadFile(queueFixture)
+	if err != nil {
+		t.Fatalf("ReadFile(%s): %v", queueFixture, err)
+	}
+
+	out, err := Specialize(src, []Substitution{{Placeholder: "T", Concrete: "Greeter"}}, nil)
+	if err != nil {
+		t.Fatalf("Specialize: %v", err)
+	}
+	got := string(out)
+
+	if strings.Contains(got, "generic.Type") || strings.Contains(got, `"generic"`) {
+		t.Errorf("output still references the generic placeholder package; output:\n%s", got)
+	}
+	for _, want := range []string{"type GreeterQueue struct", "func NewGreeterQueue() *GreeterQueue", "func (q *GreeterQueue) Push(v Greeter)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q; output:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "TQueue") || strings.Contains(got, "NewTQueue") {
+		t.Errorf("output still contains an unrenamed placeholder identifier; output:\n%s", got)
+	}
+}
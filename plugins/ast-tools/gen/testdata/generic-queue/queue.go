@@ -0,0 +1,30 @@
+// Generic queue template for testing memmem gen.
+
+package queue
+
+import "generic"
+
+// T is the placeholder element type, substituted by memmem gen.
+type T generic.Type
+
+// TQueue is a FIFO queue of T.
+type TQueue struct {
+	items []T
+}
+
+// NewTQueue returns an empty TQueue.
+func NewTQueue() *TQueue {
+	return &TQueue{}
+}
+
+// Push appends v to the back of the queue.
+func (q *TQueue) Push(v T) {
+	q.items = append(q.items, v)
+}
+
+// Pop removes and returns the item at the front of the queue.
+func (q *TQueue) Pop() T {
+	v := q.items[0]
+	q.items = q.items[1:]
+	return v
+}
@@ -0,0 +1,310 @@
+// Package lsp implements a minimal Language Server Protocol server over
+// stdio that exposes memmem's structural patterns as virtual workspace
+// symbols: workspace/symbol searches the workspace for matches of the
+// query pattern, textDocument/references re-runs the last query pattern
+// against a single file, and a custom memmem/rewrite request applies a
+// rewrite rule to a document. It reuses package pattern for matching and
+// package rewrite for the custom rewrite request.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/baleen37/memmem/plugins/ast-tools/pattern"
+	"github.com/baleen37/memmem/plugins/ast-tools/rewrite"
+)
+
+// Server is a running LSP session over a single workspace root.
+type Server struct {
+	root string
+
+	// docs caches the last known text for each open/changed document, so
+	// didChange can feed re-matching without a round trip to disk.
+	docs map[string]string
+
+	// lastQuery is the pattern text from the most recent workspace/symbol
+	// call, consulted by textDocument/references to answer "matches of the
+	// pattern under the cursor" without the client resending it.
+	lastQuery string
+}
+
+// NewServer returns a Server rooted at root (the workspace folder). root is
+// resolved to an absolute path so that pathToURI produces well-formed
+// file:// URIs and matches the absolute URIs clients send in didOpen.
+func NewServer(root string) *Server {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return &Server{root: root, docs: map[string]string{}}
+}
+
+// Serve runs the read-eval-respond loop until in is closed.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	r := bufio.NewReader(in)
+	for {
+		req, err := readMessage(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if req.Method == "" {
+			continue // a response to a request we never send
+		}
+
+		result, rpcErr := s.dispatch(req.Method, req.Params)
+		if req.ID == nil {
+			continue // notification: no response expected
+		}
+		resp := &message{JSONRPC: "2.0", ID: req.ID, Result: result}
+		if rpcErr != nil {
+			resp.Result = nil
+			resp.Error = &rpcError{Code: -32603, Message: rpcErr.Error()}
+		}
+		if err := writeMessage(out, resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) dispatch(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "initialize":
+		return s.handleInitialize(params)
+	case "workspace/symbol":
+		return s.handleWorkspaceSymbol(params)
+	case "textDocument/references":
+		return s.handleReferences(params)
+	case "textDocument/didOpen":
+		return nil, s.handleDidOpen(params)
+	case "textDocument/didChange":
+		return nil, s.handleDidChange(params)
+	case "memmem/rewrite":
+		return s.handleRewrite(params)
+	case "shutdown":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("lsp: unhandled method %q", method)
+	}
+}
+
+func (s *Server) handleInitialize(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		RootPath string `json:"rootPath"`
+		RootURI  string `json:"rootUri"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if root := uriToPath(p.RootURI); root != "" {
+		s.root = root
+	} else if p.RootPath != "" {
+		if abs, err := filepath.Abs(p.RootPath); err == nil {
+			p.RootPath = abs
+		}
+		s.root = p.RootPath
+	}
+
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"workspaceSymbolProvider": true,
+			"referencesProvider":      true,
+			"textDocumentSync":        1, // full document sync
+		},
+	}, nil
+}
+
+// workspaceSymbolParams mirrors LSP's WorkspaceSymbolParams; query carries
+// the pattern text to search for, per this server's virtual-symbol design.
+type workspaceSymbolParams struct {
+	Query string `json:"query"`
+}
+
+func (s *Server) handleWorkspaceSymbol(params json.RawMessage) (interface{}, error) {
+	var p workspaceSymbolParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Query == "" {
+		return []symbolInformation{}, nil
+	}
+
+	pat, err := pattern.Parse(p.Query)
+	if err != nil {
+		return nil, fmt.Errorf("lsp: compile pattern %q: %w", p.Query, err)
+	}
+	s.lastQuery = p.Query
+
+	var symbols []symbolInformation
+	err = s.walkGoFiles(func(path string) error {
+		fset, f, err := s.parseDoc(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range pat.Match(fset, f) {
+			symbols = append(symbols, symbolInformation{
+				Name: p.Query,
+				Kind: symbolKindFunction,
+				Location: location{
+					URI:   pathToURI(path),
+					Range: rangeFromPos(m.Pos, fset.Position(m.Node.End())),
+				},
+			})
+		}
+		return nil
+	})
+	return symbols, err
+}
+
+// referenceParams mirrors LSP's ReferenceParams.
+type referenceParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+}
+
+func (s *Server) handleReferences(params json.RawMessage) (interface{}, error) {
+	var p referenceParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if s.lastQuery == "" {
+		return []location{}, nil
+	}
+
+	pat, err := pattern.Parse(s.lastQuery)
+	if err != nil {
+		return nil, err
+	}
+	path := uriToPath(p.TextDocument.URI)
+	fset, f, err := s.parseDoc(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var locs []location
+	for _, m := range pat.Match(fset, f) {
+		locs = append(locs, location{
+			URI:   p.TextDocument.URI,
+			Range: rangeFromPos(m.Pos, fset.Position(m.Node.End())),
+		})
+	}
+	return locs, nil
+}
+
+// rewriteParams are the parameters for the custom memmem/rewrite request:
+// apply a from -> to rule to the document identified by uri.
+type rewriteParams struct {
+	URI  string `json:"uri"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (s *Server) handleRewrite(params json.RawMessage) (interface{}, error) {
+	var p rewriteParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	rw, err := rewrite.Compile(p.From, p.To)
+	if err != nil {
+		return nil, err
+	}
+
+	path := uriToPath(p.URI)
+	fset, f, err := s.parseDoc(path)
+	if err != nil {
+		return nil, err
+	}
+	if !rw.Apply(fset, f) {
+		return []textEdit{}, nil
+	}
+
+	out, err := rewrite.Source(fset, f)
+	if err != nil {
+		return nil, err
+	}
+	original, ok := s.docs[pathToURI(path)]
+	if !ok {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		original = string(src)
+	}
+	s.docs[p.URI] = string(out)
+
+	return []textEdit{{
+		Range:   fullDocumentRange(original),
+		NewText: string(out),
+	}}, nil
+}
+
+func (s *Server) handleDidOpen(params json.RawMessage) error {
+	var p struct {
+		TextDocument struct {
+			URI  string `json:"uri"`
+			Text string `json:"text"`
+		} `json:"textDocument"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+	s.docs[p.TextDocument.URI] = p.TextDocument.Text
+	return nil
+}
+
+func (s *Server) handleDidChange(params json.RawMessage) error {
+	var p struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+	if len(p.ContentChanges) == 0 {
+		return nil
+	}
+	// Full-document sync only: the last change carries the whole text.
+	s.docs[p.TextDocument.URI] = p.ContentChanges[len(p.ContentChanges)-1].Text
+	return nil
+}
+
+// parseDoc parses path, preferring the in-memory buffer from didOpen or
+// didChange over the file on disk so edits are reflected without a save.
+func (s *Server) parseDoc(path string) (*token.FileSet, *ast.File, error) {
+	fset := token.NewFileSet()
+	if src, ok := s.docs[pathToURI(path)]; ok {
+		f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		return fset, f, err
+	}
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	return fset, f, err
+}
+
+// walkGoFiles calls fn for every .go file under the server's workspace root.
+func (s *Server) walkGoFiles(fn func(path string) error) error {
+	return filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		return fn(path)
+	})
+}
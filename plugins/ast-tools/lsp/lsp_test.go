@@ -0,0 +1,39 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const sampleFixtureDir = "../tests/fixtures/multi-lang"
+
+func TestHandleWorkspaceSymbol_FuncReturningStringOverSample(t *testing.T) {
+	srv := NewServer(sampleFixtureDir)
+
+	params, err := json.Marshal(workspaceSymbolParams{Query: "func $name($_) string { return $_ }"})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	result, err := srv.handleWorkspaceSymbol(params)
+	if err != nil {
+		t.Fatalf("handleWorkspaceSymbol: %v", err)
+	}
+	symbols, ok := result.([]symbolInformation)
+	if !ok {
+		t.Fatalf("handleWorkspaceSymbol returned %T, want []symbolInformation", result)
+	}
+
+	got := map[string]bool{}
+	for _, sym := range symbols {
+		got[sym.Name] = true
+	}
+	// The server names every symbol after the matched query pattern, not
+	// the captured identifier, so both of sample.go's matches share one name.
+	if len(symbols) != 2 || !got["func $name($_) string { return $_ }"] {
+		t.Fatalf("handleWorkspaceSymbol returned %+v, want 2 symbols named after the query pattern", symbols)
+	}
+	if srv.lastQuery != "func $name($_) string { return $_ }" {
+		t.Errorf("lastQuery = %q, want the query to be recorded for textDocument/references", srv.lastQuery)
+	}
+}
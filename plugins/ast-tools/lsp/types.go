@@ -0,0 +1,80 @@
+package lsp
+
+import (
+	"go/token"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// position is an LSP Position: zero-based line and UTF-16 code unit.
+// Character is derived straight from token.Position's (1-based, byte-based)
+// Column, so it equals the UTF-16 offset only where the preceding text on
+// the line is ASCII.
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+// symbolKindFunction is the LSP SymbolKind value for "Function", used for
+// every virtual symbol since a matched pattern isn't any one kind.
+const symbolKindFunction = 12
+
+type symbolInformation struct {
+	Name     string   `json:"name"`
+	Kind     int      `json:"kind"`
+	Location location `json:"location"`
+}
+
+type textEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+func rangeFromPos(start, end token.Position) lspRange {
+	return lspRange{
+		Start: position{Line: start.Line - 1, Character: start.Column - 1},
+		End:   position{Line: end.Line - 1, Character: end.Column - 1},
+	}
+}
+
+// fullDocumentRange spans all of text, for a memmem/rewrite response that
+// replaces the whole document (text being its content before the rewrite)
+// with its rewritten form.
+func fullDocumentRange(text string) lspRange {
+	lines := strings.Split(text, "\n")
+	lastLine := lines[len(lines)-1]
+	return lspRange{
+		Start: position{Line: 0, Character: 0},
+		End:   position{Line: len(lines) - 1, Character: len(lastLine)},
+	}
+}
+
+// pathToURI and uriToPath convert between filesystem paths and the
+// file:// URIs LSP uses on the wire. pathToURI makes path absolute first:
+// a relative Path on a file:// URL serializes as a host component instead
+// of a path, producing a URI that doesn't round-trip through uriToPath.
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return u.Path
+}
@@ -0,0 +1,150 @@
+// Package output renders pattern matches in formats meant for editors,
+// fzf, and CI: a stable JSON/JSONL schema, and grep/vimgrep-compatible
+// plain text.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Capture describes a single metavariable binding within a Match.
+type Capture struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Col     int    `json:"col"`
+	Snippet string `json:"snippet"`
+}
+
+// Match is the stable schema for a single pattern match, shared by every
+// Writer implementation.
+type Match struct {
+	File     string             `json:"file"`
+	Line     int                `json:"line"`
+	Col      int                `json:"col"`
+	EndLine  int                `json:"end_line"`
+	EndCol   int                `json:"end_col"`
+	Kind     string             `json:"kind"`
+	Snippet  string             `json:"snippet"`
+	Captures map[string]Capture `json:"captures,omitempty"`
+}
+
+// Stats summarizes a completed stream of matches.
+type Stats struct {
+	Files   int `json:"files"`
+	Matches int `json:"matches"`
+}
+
+// Writer receives matches as they're found and flushes any buffering on
+// Close. It does not print a summary itself; callers that pass --stats
+// print one separately with PrintStats so the line is opt-in.
+type Writer interface {
+	Write(m Match) error
+	Close() error
+}
+
+// PrintStats writes a one-line summary of a completed match stream, for
+// callers that passed --stats.
+func PrintStats(w io.Writer, stats Stats) error {
+	_, err := fmt.Fprintf(w, "%d matches in %d files\n", stats.Matches, stats.Files)
+	return err
+}
+
+// jsonlWriter emits one JSON object per line, suitable for streaming into
+// jq, fzf, or a editor plugin as matches are produced.
+type jsonlWriter struct {
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewJSONLWriter returns a Writer that emits newline-delimited JSON.
+func NewJSONLWriter(w io.Writer) Writer {
+	return &jsonlWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+func (j *jsonlWriter) Write(m Match) error {
+	return j.enc.Encode(m)
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
+
+// jsonWriter collects every match and emits a single JSON array on Close,
+// for callers that want the whole result set at once.
+type jsonWriter struct {
+	w       io.Writer
+	matches []Match
+}
+
+// NewJSONWriter returns a Writer that emits a single JSON array of matches.
+func NewJSONWriter(w io.Writer) Writer {
+	return &jsonWriter{w: w}
+}
+
+func (j *jsonWriter) Write(m Match) error {
+	j.matches = append(j.matches, m)
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j.matches)
+}
+
+// grepWriter renders file:line:snippet, matching the output of grep -n, or
+// file:line:col:snippet in vimgrep mode, so results can feed tools that
+// already parse those conventions. Only the first line of a multi-line
+// snippet is printed, keeping one output line per match.
+type grepWriter struct {
+	w       io.Writer
+	vimgrep bool
+}
+
+// NewGrepWriter returns a Writer producing grep -n compatible lines.
+func NewGrepWriter(w io.Writer) Writer {
+	return &grepWriter{w: w}
+}
+
+// NewVimgrepWriter returns a Writer producing file:line:col:snippet lines
+// for Vim's quickfix/:grep integration.
+func NewVimgrepWriter(w io.Writer) Writer {
+	return &grepWriter{w: w, vimgrep: true}
+}
+
+func (g *grepWriter) Write(m Match) error {
+	snippet := m.Snippet
+	if i := strings.IndexByte(snippet, '\n'); i >= 0 {
+		snippet = snippet[:i]
+	}
+	if g.vimgrep {
+		_, err := fmt.Fprintf(g.w, "%s:%d:%d:%s\n", m.File, m.Line, m.Col, snippet)
+		return err
+	}
+	_, err := fmt.Fprintf(g.w, "%s:%d:%s\n", m.File, m.Line, snippet)
+	return err
+}
+
+func (g *grepWriter) Close() error {
+	return nil
+}
+
+// New returns the Writer for the named --format value: "json", "jsonl",
+// "grep", or "vimgrep".
+func New(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case "json":
+		return NewJSONWriter(w), nil
+	case "jsonl", "":
+		return NewJSONLWriter(w), nil
+	case "grep":
+		return NewGrepWriter(w), nil
+	case "vimgrep":
+		return NewVimgrepWriter(w), nil
+	default:
+		return nil, fmt.Errorf("output: unknown format %q", format)
+	}
+}
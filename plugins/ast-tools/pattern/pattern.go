@@ -0,0 +1,313 @@
+// Package pattern implements a small structural pattern language for Go
+// source, inspired by gofmt -r and comby. A pattern is itself a snippet of
+// Go source; identifiers of the form $name are treated as metavariables
+// that unify with whatever AST node occupies their position.
+package pattern
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// Pattern is a compiled pattern ready to be matched against a parsed file.
+type Pattern struct {
+	Name string
+	node ast.Node
+	fset *token.FileSet
+}
+
+// Node returns the residual AST the pattern compiles to, with metavariable
+// identifiers still in place. Callers such as package rewrite use it as a
+// substitution template.
+func (p *Pattern) Node() ast.Node {
+	return p.node
+}
+
+// Match is a single successful match of a Pattern against a node.
+type Match struct {
+	Node     ast.Node
+	Pos      token.Position
+	Captures map[string]ast.Node
+}
+
+// holePrefix marks a metavariable identifier once it's been rewritten into
+// legal Go syntax, e.g. $x becomes __hole_x. "$" itself isn't a legal Go
+// identifier character - go/scanner rejects it outright - so Parse never
+// hands raw "$name" text to go/parser; holeEscapeRe performs the rewrite
+// first, and holeName strips holePrefix back off to recover name.
+const holePrefix = "__hole_"
+
+// isWildcard reports whether name is the anonymous hole "_".
+func isWildcard(name string) bool {
+	return name == "_"
+}
+
+// variadicHoleRe recognizes the $fn(...) variadic-capture form in pattern
+// source. Bare "(...)" isn't valid Go syntax, so Parse rewrites it to a
+// plain identifier ending in variadicHoleSuffix before handing src to
+// go/parser; unifyArgs recognizes the suffix to restore the original intent.
+var variadicHoleRe = regexp.MustCompile(`\$(\w+)\(\.\.\.\)`)
+
+const variadicHoleSuffix = "__rest__"
+
+// holeEscapeRe recognizes a metavariable token ($x, $_, or a
+// variadicHoleRe-rewritten $fn__rest__) and rewrites it to a legal Go
+// identifier carrying holePrefix, since go/scanner treats a bare "$" as an
+// illegal character and parser.ParseFile would otherwise fail on every
+// pattern that uses one.
+var holeEscapeRe = regexp.MustCompile(`\$(\w+)`)
+
+// Parse compiles src into a Pattern. src may be an expression, a statement,
+// or a full declaration; Parse wraps it in a synthetic package and function
+// as needed so that go/parser can produce a valid AST, then unwraps the
+// result back to the node the caller actually wrote.
+func Parse(src string) (*Pattern, error) {
+	fset := token.NewFileSet()
+
+	src = variadicHoleRe.ReplaceAllString(src, "$$${1}"+variadicHoleSuffix)
+	src = holeEscapeRe.ReplaceAllString(src, holePrefix+"${1}")
+
+	wrapped := "package p\nfunc _pattern_() {\n" + src + "\n}\n"
+	f, err := parser.ParseFile(fset, "pattern.go", wrapped, parser.ParseComments)
+	if err != nil {
+		// Fall back to treating src as a full declaration (e.g. a func decl
+		// pattern like `func $name($_) string { return $_ }`).
+		declSrc := "package p\n" + src + "\n"
+		f, err = parser.ParseFile(fset, "pattern.go", declSrc, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("pattern: parse %q: %w", src, err)
+		}
+		if len(f.Decls) != 1 {
+			return nil, fmt.Errorf("pattern: expected exactly one declaration in %q", src)
+		}
+		return &Pattern{node: f.Decls[0], fset: fset}, nil
+	}
+
+	fn := f.Decls[0].(*ast.FuncDecl)
+	body := fn.Body.List
+	if len(body) == 1 {
+		if es, ok := body[0].(*ast.ExprStmt); ok {
+			return &Pattern{node: es.X, fset: fset}, nil
+		}
+		return &Pattern{node: body[0], fset: fset}, nil
+	}
+	return &Pattern{node: fn.Body, fset: fset}, nil
+}
+
+// Match walks root with ast.Inspect and returns every node that structurally
+// unifies with the pattern, ignoring token.Pos and comments.
+func (p *Pattern) Match(fset *token.FileSet, root ast.Node) []Match {
+	var matches []Match
+	ast.Inspect(root, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		captures := map[string]ast.Node{}
+		if unify(p.node, n, captures) {
+			matches = append(matches, Match{
+				Node:     n,
+				Pos:      fset.Position(n.Pos()),
+				Captures: captures,
+			})
+		}
+		return true
+	})
+	return matches
+}
+
+// holeName returns the metavariable name of ident if it was compiled from a
+// $name (holeEscapeRe having since rewritten it to __hole_name), and
+// ok=false otherwise.
+func holeName(ident *ast.Ident) (name string, ok bool) {
+	if ident == nil || !strings.HasPrefix(ident.Name, holePrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(ident.Name, holePrefix), true
+}
+
+// HoleName reports the metavariable name of ident (e.g. "x" for $x, and
+// "fn" for a $fn(...) variadic hole), the same key Match uses in Captures,
+// for use by consumers, such as package rewrite, that build their own trees
+// from matched patterns.
+func HoleName(ident *ast.Ident) (name string, ok bool) {
+	name, ok = holeName(ident)
+	return strings.TrimSuffix(name, variadicHoleSuffix), ok
+}
+
+// unify compares pat against n, binding metavariables into captures and
+// enforcing that repeated uses of the same name unify with equal nodes.
+func unify(pat, n ast.Node, captures map[string]ast.Node) bool {
+	if pat == nil || n == nil {
+		return pat == n
+	}
+
+	if id, ok := pat.(*ast.Ident); ok {
+		if name, isHole := holeName(id); isHole {
+			if isWildcard(name) {
+				return true
+			}
+			if prior, seen := captures[name]; seen {
+				return equalNode(prior, n)
+			}
+			captures[name] = n
+			return true
+		}
+	}
+
+	switch p := pat.(type) {
+	case *ast.Ident:
+		id, ok := n.(*ast.Ident)
+		return ok && p.Name == id.Name
+	case *ast.BasicLit:
+		lit, ok := n.(*ast.BasicLit)
+		return ok && p.Kind == lit.Kind && p.Value == lit.Value
+	case *ast.CallExpr:
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !unify(p.Fun, call.Fun, captures) {
+			return false
+		}
+		return unifyArgs(p.Args, call.Args, captures)
+	case *ast.BinaryExpr:
+		b, ok := n.(*ast.BinaryExpr)
+		return ok && p.Op == b.Op && unify(p.X, b.X, captures) && unify(p.Y, b.Y, captures)
+	case *ast.ReturnStmt:
+		r, ok := n.(*ast.ReturnStmt)
+		if !ok || len(p.Results) != len(r.Results) {
+			return false
+		}
+		for i := range p.Results {
+			if !unify(p.Results[i], r.Results[i], captures) {
+				return false
+			}
+		}
+		return true
+	case *ast.ExprStmt:
+		e, ok := n.(*ast.ExprStmt)
+		return ok && unify(p.X, e.X, captures)
+	case *ast.SelectorExpr:
+		s, ok := n.(*ast.SelectorExpr)
+		return ok && unify(p.X, s.X, captures) && unify(p.Sel, s.Sel, captures)
+	case *ast.FuncDecl:
+		fd, ok := n.(*ast.FuncDecl)
+		return ok && unify(p.Name, fd.Name, captures) && unify(p.Type, fd.Type, captures) && unify(p.Body, fd.Body, captures)
+	case *ast.FuncType:
+		ft, ok := n.(*ast.FuncType)
+		if !ok {
+			return false
+		}
+		return unifyFields(p.Params, ft.Params, captures) && unifyFields(p.Results, ft.Results, captures)
+	case *ast.BlockStmt:
+		b, ok := n.(*ast.BlockStmt)
+		if !ok || len(p.List) != len(b.List) {
+			return false
+		}
+		for i := range p.List {
+			if !unify(p.List[i], b.List[i], captures) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// flattenFields expands a parameter or result list into one entry per
+// parameter slot, so that a field declaring multiple names under one type
+// (func(a, b string)) and an unnamed field (func(string)) both compare by
+// slot rather than by ast.Field shape.
+func flattenFields(fl *ast.FieldList) []ast.Expr {
+	if fl == nil {
+		return nil
+	}
+	var out []ast.Expr
+	for _, f := range fl.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			out = append(out, f.Type)
+		}
+	}
+	return out
+}
+
+// unifyFields compares two parameter or result lists slot by slot, so a
+// pattern's $_ type wildcard matches any single parameter regardless of
+// its real name or type.
+func unifyFields(pat, n *ast.FieldList, captures map[string]ast.Node) bool {
+	patSlots := flattenFields(pat)
+	nSlots := flattenFields(n)
+	if len(patSlots) != len(nSlots) {
+		return false
+	}
+	for i := range patSlots {
+		if !unify(patSlots[i], nSlots[i], captures) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExprList is a synthetic ast.Node binding a variadic metavariable (written
+// as a trailing $name(...) argument in a call pattern, e.g.
+// fmt.Sprintf($fmt, $args(...))) to the list of actual arguments it matched.
+type ExprList struct {
+	Exprs []ast.Expr
+}
+
+func (l *ExprList) Pos() token.Pos {
+	if len(l.Exprs) == 0 {
+		return token.NoPos
+	}
+	return l.Exprs[0].Pos()
+}
+
+func (l *ExprList) End() token.Pos {
+	if len(l.Exprs) == 0 {
+		return token.NoPos
+	}
+	return l.Exprs[len(l.Exprs)-1].End()
+}
+
+// unifyArgs unifies a pattern argument list against actual call arguments.
+// A pattern ending in a $fn(...) hole captures every remaining argument as
+// an *ExprList instead of requiring an exact count.
+func unifyArgs(pat, args []ast.Expr, captures map[string]ast.Node) bool {
+	if n := len(pat); n > 0 {
+		if id, ok := pat[n-1].(*ast.Ident); ok {
+			if name, isHole := holeName(id); isHole && strings.HasSuffix(name, variadicHoleSuffix) {
+				if n-1 > len(args) {
+					return false
+				}
+				for i := 0; i < n-1; i++ {
+					if !unify(pat[i], args[i], captures) {
+						return false
+					}
+				}
+				captures[strings.TrimSuffix(name, variadicHoleSuffix)] = &ExprList{Exprs: args[n-1:]}
+				return true
+			}
+		}
+	}
+	if len(pat) != len(args) {
+		return false
+	}
+	for i := range pat {
+		if !unify(pat[i], args[i], captures) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalNode reports whether two previously-bound nodes are structurally
+// identical, used to enforce that repeated metavariable names unify.
+func equalNode(a, b ast.Node) bool {
+	return unify(a, b, map[string]ast.Node{})
+}
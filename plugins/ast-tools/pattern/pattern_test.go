@@ -0,0 +1,57 @@
+package pattern
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+const sampleFixture = "../tests/fixtures/multi-lang/sample.go"
+
+func TestParse_MetavariableTokensAreLegalSyntax(t *testing.T) {
+	// $x, $_, and $fn(...) are not legal Go syntax on their own; Parse must
+	// rewrite them before handing the string to go/parser.
+	for _, src := range []string{
+		"$x + 1",
+		"func $name($_) string { return $_ }",
+		`fmt.Sprintf("%s, %s!", $g, $n)`,
+	} {
+		if _, err := Parse(src); err != nil {
+			t.Errorf("Parse(%q) = %v, want no error", src, err)
+		}
+	}
+}
+
+func TestMatch_FuncReturningStringOverSample(t *testing.T) {
+	pat, err := Parse("func $name($_) string { return $_ }")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, sampleFixture, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile(%s): %v", sampleFixture, err)
+	}
+
+	matches := pat.Match(fset, f)
+	got := map[string]bool{}
+	for _, m := range matches {
+		id, ok := m.Captures["name"].(*ast.Ident)
+		if !ok {
+			t.Fatalf("match %+v has no captured $name identifier", m)
+		}
+		got[id.Name] = true
+	}
+
+	want := map[string]bool{"Greet": true, "Farewell": true}
+	if len(got) != len(want) {
+		t.Fatalf("Match captured names %v, want %v", got, want)
+	}
+	for name := range want {
+		if !got[name] {
+			t.Errorf("Match did not find %s", name)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package rewrite implements gofmt -r style structural rewriting: a "from"
+// pattern is matched with package pattern, and each match is replaced by a
+// "to" template with the match's captures substituted in.
+package rewrite
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"text/tabwriter"
+
+	"github.com/baleen37/memmem/plugins/ast-tools/pattern"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Rewriter applies a single from -> to rewrite rule to parsed files.
+type Rewriter struct {
+	from *pattern.Pattern
+	to   *pattern.Pattern
+}
+
+// Compile parses the from and to patterns and returns a Rewriter that
+// substitutes to's metavariables with whatever from captured.
+func Compile(from, to string) (*Rewriter, error) {
+	fromPat, err := pattern.Parse(from)
+	if err != nil {
+		return nil, fmt.Errorf("rewrite: compile from %q: %w", from, err)
+	}
+	toPat, err := pattern.Parse(to)
+	if err != nil {
+		return nil, fmt.Errorf("rewrite: compile to %q: %w", to, err)
+	}
+	return &Rewriter{from: fromPat, to: toPat}, nil
+}
+
+// Apply walks f and replaces every node matching the from pattern with a
+// copy of the to pattern, substituted with that match's captures. It
+// reports whether any replacement was made.
+func (r *Rewriter) Apply(fset *token.FileSet, f *ast.File) (changed bool) {
+	matches := r.from.Match(fset, f)
+	if len(matches) == 0 {
+		return false
+	}
+
+	byNode := make(map[ast.Node]pattern.Match, len(matches))
+	for _, m := range matches {
+		byNode[m.Node] = m
+	}
+
+	astutil.Apply(f, nil, func(c *astutil.Cursor) bool {
+		n := c.Node()
+		if n == nil {
+			return true
+		}
+		m, ok := byNode[n]
+		if !ok {
+			return true
+		}
+		c.Replace(substitute(r.to.Node(), m.Captures))
+		changed = true
+		return true
+	})
+	return changed
+}
+
+// Source renders f back to formatted Go source, preserving comments.
+func Source(fset *token.FileSet, f *ast.File) ([]byte, error) {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 1, '\t', tabwriter.DiscardEmptyColumns)
+	cfg := &printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := cfg.Fprint(tw, fset, f); err != nil {
+		return nil, fmt.Errorf("rewrite: print: %w", err)
+	}
+	if err := tw.Flush(); err != nil {
+		return nil, fmt.Errorf("rewrite: flush: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// substitute returns a copy of tmpl with every metavariable identifier
+// replaced by the node it captured in a from-match. It handles the
+// expression and statement kinds pattern.unify understands (identifiers,
+// literals, calls, binary and selector expressions, and return, expression
+// and block statements); any other node, including *ast.FuncDecl and
+// *ast.FuncType, is returned as is, with no metavariables substituted
+// inside it. A trailing
+// $fn(...) argument in a CallExpr is spliced in as a slice rather than a
+// single node, since it stands for zero or more captured expressions.
+func substitute(tmpl ast.Node, captures map[string]ast.Node) ast.Node {
+	if id, ok := tmpl.(*ast.Ident); ok {
+		if name, isHole := pattern.HoleName(id); isHole {
+			if bound, ok := captures[name]; ok {
+				return bound
+			}
+		}
+		return id
+	}
+
+	switch t := tmpl.(type) {
+	case *ast.BasicLit:
+		cp := *t
+		return &cp
+	case *ast.CallExpr:
+		cp := *t
+		cp.Fun = substitute(t.Fun, captures).(ast.Expr)
+		cp.Args = substituteArgs(t.Args, captures)
+		return &cp
+	case *ast.BinaryExpr:
+		cp := *t
+		cp.X = substitute(t.X, captures).(ast.Expr)
+		cp.Y = substitute(t.Y, captures).(ast.Expr)
+		return &cp
+	case *ast.ReturnStmt:
+		cp := *t
+		cp.Results = make([]ast.Expr, len(t.Results))
+		for i, r := range t.Results {
+			cp.Results[i] = substitute(r, captures).(ast.Expr)
+		}
+		return &cp
+	case *ast.ExprStmt:
+		cp := *t
+		cp.X = substitute(t.X, captures).(ast.Expr)
+		return &cp
+	case *ast.SelectorExpr:
+		cp := *t
+		cp.X = substitute(t.X, captures).(ast.Expr)
+		cp.Sel = substitute(t.Sel, captures).(*ast.Ident)
+		return &cp
+	case *ast.BlockStmt:
+		cp := *t
+		cp.List = make([]ast.Stmt, len(t.List))
+		for i, s := range t.List {
+			cp.List[i] = substitute(s, captures).(ast.Stmt)
+		}
+		return &cp
+	default:
+		return tmpl
+	}
+}
+
+// substituteArgs expands a template's argument list, splicing a trailing
+// variadic-hole argument into the zero or more expressions it captured.
+func substituteArgs(args []ast.Expr, captures map[string]ast.Node) []ast.Expr {
+	out := make([]ast.Expr, 0, len(args))
+	for _, a := range args {
+		if id, ok := a.(*ast.Ident); ok {
+			if name, isHole := pattern.HoleName(id); isHole {
+				if bound, ok := captures[name].(*pattern.ExprList); ok {
+					out = append(out, bound.Exprs...)
+					continue
+				}
+			}
+		}
+		out = append(out, substitute(a, captures).(ast.Expr))
+	}
+	return out
+}
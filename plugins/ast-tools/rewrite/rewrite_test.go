@@ -0,0 +1,48 @@
+package rewrite
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const sampleFixture = "../tests/fixtures/multi-lang/sample.go"
+
+func TestApply_SprintfNewlineOverSample(t *testing.T) {
+	rw, err := Compile(
+		`fmt.Sprintf("%s, %s!", $g, $n)`,
+		`fmt.Sprintf("%s, %s!\n", $g, $n)`,
+	)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, sampleFixture, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile(%s): %v", sampleFixture, err)
+	}
+
+	if !rw.Apply(fset, f) {
+		t.Fatal("Apply reported no change, want Greet's Sprintf call rewritten")
+	}
+
+	out, err := Source(fset, f)
+	if err != nil {
+		t.Fatalf("Source: %v", err)
+	}
+
+	// Only Greet's Sprintf call has both the literal "%s, %s!" and two
+	// arguments; Farewell's single-argument "Goodbye, %s!" call doesn't
+	// match and must be left untouched.
+	if got := strings.Count(string(out), `%s, %s!\n`); got != 1 {
+		t.Errorf("rewritten source has %d occurrences of the new format string, want 1 (Greet only); output:\n%s", got, out)
+	}
+	if strings.Contains(string(out), `"%s, %s!"`) {
+		t.Errorf("rewritten source still contains the old format string; output:\n%s", out)
+	}
+	if !strings.Contains(string(out), `"Goodbye, %s!"`) {
+		t.Errorf("rewritten source lost Farewell's unrelated Sprintf call; output:\n%s", out)
+	}
+}
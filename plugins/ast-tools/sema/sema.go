@@ -0,0 +1,192 @@
+// Package sema answers semantic questions about a loaded Go program using
+// go/types and golang.org/x/tools/go/packages, complementing the purely
+// syntactic matching in package pattern with queries like "what implements
+// this interface" or "what has this method signature".
+package sema
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Program is a type-checked set of packages ready for semantic queries.
+type Program struct {
+	pkgs []*packages.Package
+}
+
+// loadMode requests the syntax trees, type information, and dependency
+// graph needed to resolve interface and method queries.
+const loadMode = packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo |
+	packages.NeedSyntax | packages.NeedDeps | packages.NeedImports
+
+// LoadPackages type-checks the packages matching patterns (in the same
+// syntax accepted by `go build`, e.g. "./..." or a file path) and returns a
+// Program that can be queried for semantic properties.
+func LoadPackages(patterns ...string) (*Program, error) {
+	cfg := &packages.Config{Mode: loadMode}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("sema: load %v: %w", patterns, err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("sema: errors loading %v", patterns)
+	}
+	return &Program{pkgs: pkgs}, nil
+}
+
+// Implementors returns every named type in the program that implements
+// iface, given as a qualified interface name such as "fmt.Stringer".
+func (p *Program) Implementors(iface string) []types.Object {
+	ifaceType, err := p.lookupInterface(iface)
+	if err != nil || ifaceType == nil {
+		return nil
+	}
+
+	var found []types.Object
+	seen := map[types.Object]bool{}
+	for _, pkg := range p.pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			tn, ok := obj.(*types.TypeName)
+			if !ok || seen[tn] {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			if types.Implements(named, ifaceType) || types.Implements(types.NewPointer(named), ifaceType) {
+				seen[tn] = true
+				found = append(found, tn)
+			}
+		}
+	}
+	return found
+}
+
+// MethodsMatching returns every method in the program whose signature
+// renders identical to sig, e.g. "func(string) string". sig is expected in
+// parameter-name-free form, the same form signatureString renders a
+// method's own *types.Signature in, so callers don't need to already know
+// the source's parameter identifiers.
+func (p *Program) MethodsMatching(sig string) []types.Object {
+	want := normalizeSig(sig)
+
+	var found []types.Object
+	for _, pkg := range p.pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			for i := 0; i < named.NumMethods(); i++ {
+				m := named.Method(i)
+				got := signatureString(m.Type().(*types.Signature))
+				if normalizeSig(got) == want {
+					found = append(found, m)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// signatureString renders sig as "func(paramType, ...) resultType", with
+// parameter names omitted: types.Signature.String() includes the source's
+// parameter names (e.g. "func(name string) string"), which would force
+// every caller of MethodsMatching to already know identifiers they're
+// trying to discover.
+func signatureString(sig *types.Signature) string {
+	var b strings.Builder
+	b.WriteString("func(")
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		t := params.At(i).Type()
+		if sig.Variadic() && i == params.Len()-1 {
+			b.WriteString("...")
+			t = t.(*types.Slice).Elem()
+		}
+		b.WriteString(types.TypeString(t, nil))
+	}
+	b.WriteString(")")
+
+	results := sig.Results()
+	switch results.Len() {
+	case 0:
+	case 1:
+		b.WriteString(" ")
+		b.WriteString(types.TypeString(results.At(0).Type(), nil))
+	default:
+		b.WriteString(" (")
+		for i := 0; i < results.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(types.TypeString(results.At(i).Type(), nil))
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// normalizeSig collapses each run of whitespace to a single space, so
+// signatures that differ only in how much spacing they use compare equal;
+// spacing present in one and absent in the other (as in "func(string)string"
+// against "func(string) string") still makes them differ.
+func normalizeSig(sig string) string {
+	return strings.Join(strings.Fields(sig), " ")
+}
+
+// lookupInterface resolves a qualified interface name, such as
+// "fmt.Stringer", to its *types.Interface by searching the type-checked
+// packages' imports for the named package and type.
+func (p *Program) lookupInterface(qualified string) (*types.Interface, error) {
+	parts := strings.SplitN(qualified, ".", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("sema: %q is not a package-qualified interface name", qualified)
+	}
+	pkgName, typeName := parts[0], parts[1]
+
+	for _, pkg := range p.pkgs {
+		target := findImport(pkg, pkgName)
+		if target == nil {
+			continue
+		}
+		obj := target.Types.Scope().Lookup(typeName)
+		tn, ok := obj.(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if iface, ok := tn.Type().Underlying().(*types.Interface); ok {
+			return iface, nil
+		}
+	}
+	return nil, fmt.Errorf("sema: interface %q not found among loaded packages' imports", qualified)
+}
+
+// findImport searches pkg's import graph (including itself) for a package
+// whose name matches name.
+func findImport(pkg *packages.Package, name string) *packages.Package {
+	if pkg.Types != nil && pkg.Types.Name() == name {
+		return pkg
+	}
+	for _, imp := range pkg.Imports {
+		if imp.Types != nil && imp.Types.Name() == name {
+			return imp
+		}
+	}
+	return nil
+}
+
@@ -0,0 +1,20 @@
+package sema
+
+import "testing"
+
+const sampleFixture = "../tests/fixtures/multi-lang/sample.go"
+
+func TestMethodsMatching_IgnoresParameterNames(t *testing.T) {
+	prog, err := LoadPackages(sampleFixture)
+	if err != nil {
+		t.Fatalf("LoadPackages(%s): %v", sampleFixture, err)
+	}
+
+	// The query omits parameter names, as the request's own example does
+	// ("func(string) string"), even though Greet's declared signature in
+	// the fixture is "func(name string) string".
+	found := prog.MethodsMatching("func(string) string")
+	if len(found) != 1 || found[0].Name() != "Greet" {
+		t.Fatalf("MethodsMatching(\"func(string) string\") = %v, want [Greet]", found)
+	}
+}